@@ -0,0 +1,311 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+)
+
+// gateway holds the single pooled Dgraph client every handler shares,
+// plus the in-memory transaction store that lets a REST client spread
+// one Dgraph transaction across several HTTP requests (query, mutate,
+// then commit or abort).
+type gateway struct {
+	client api.DgraphClient
+
+	mu   sync.Mutex
+	txns map[string]*txnState
+}
+
+func newGateway(client api.DgraphClient) *gateway {
+	return &gateway{client: client, txns: make(map[string]*txnState)}
+}
+
+// txnState is the gateway's bookkeeping for one client-visible
+// transaction. Keys/Preds accumulate across every request made under
+// this txn_id (mirroring dgo's Txn.mergeContext) instead of being
+// overwritten by the latest response, since Dgraph's conflict detection
+// at commit time needs the full set of keys/predicates touched since the
+// transaction began, not just the last one.
+type txnState struct {
+	startTs uint64
+	hash    string
+	keys    map[string]struct{}
+	preds   map[string]struct{}
+}
+
+func newTxnState(ctx *api.TxnContext) *txnState {
+	t := &txnState{startTs: ctx.GetStartTs(), keys: make(map[string]struct{}), preds: make(map[string]struct{})}
+	t.merge(ctx)
+	return t
+}
+
+func (t *txnState) merge(ctx *api.TxnContext) {
+	if ctx == nil {
+		return
+	}
+	t.hash = ctx.GetHash()
+	for _, key := range ctx.GetKeys() {
+		t.keys[key] = struct{}{}
+	}
+	for _, pred := range ctx.GetPreds() {
+		t.preds[pred] = struct{}{}
+	}
+}
+
+// context rebuilds the api.TxnContext Dgraph expects at commit/abort time
+// from the accumulated key/predicate sets.
+func (t *txnState) context() *api.TxnContext {
+	ctx := &api.TxnContext{StartTs: t.startTs, Hash: t.hash}
+	for key := range t.keys {
+		ctx.Keys = append(ctx.Keys, key)
+	}
+	for pred := range t.preds {
+		ctx.Preds = append(ctx.Preds, pred)
+	}
+	return ctx
+}
+
+func (gw *gateway) putTxn(txn *api.TxnContext) string {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	id := newTxnID()
+	gw.txns[id] = newTxnState(txn)
+	return id
+}
+
+func (gw *gateway) getTxn(id string) (*txnState, bool) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	txn, ok := gw.txns[id]
+	return txn, ok
+}
+
+// mergeTxn folds ctx's Keys/Preds into the stored transaction state for
+// id, if id is still tracked.
+func (gw *gateway) mergeTxn(id string, ctx *api.TxnContext) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	if txn, ok := gw.txns[id]; ok {
+		txn.merge(ctx)
+	}
+}
+
+func (gw *gateway) dropTxn(id string) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+	delete(gw.txns, id)
+}
+
+func newTxnID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// queryRequest is the POST /v1/query body: a DQL query plus variables,
+// optionally scoped to an existing transaction.
+type queryRequest struct {
+	Query      string            `json:"query"`
+	Vars       map[string]string `json:"vars,omitempty"`
+	TxnID      string            `json:"txn_id,omitempty"`
+	ReadOnly   bool              `json:"read_only,omitempty"`
+	BestEffort bool              `json:"best_effort,omitempty"`
+}
+
+// txnResponse is embedded in query/mutate responses so a caller can
+// thread the returned txn_id into subsequent requests and into
+// POST /v1/txn/{id}/commit|abort.
+type txnResponse struct {
+	Json  json.RawMessage `json:"json"`
+	TxnID string          `json:"txn_id,omitempty"`
+}
+
+func (gw *gateway) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	apiReq := &api.Request{
+		Query:      req.Query,
+		Vars:       req.Vars,
+		ReadOnly:   req.ReadOnly,
+		BestEffort: req.BestEffort,
+	}
+	if req.TxnID != "" {
+		txn, ok := gw.getTxn(req.TxnID)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown txn_id"})
+			return
+		}
+		apiReq.StartTs = txn.startTs
+	}
+
+	ctx := withAuthorization(r.Context(), r)
+	resp, err := gw.client.Query(ctx, apiReq)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	txnID := req.TxnID
+	if txnID == "" && resp.GetTxn() != nil {
+		txnID = gw.putTxn(resp.GetTxn())
+	} else if txnID != "" {
+		gw.mergeTxn(txnID, resp.GetTxn())
+	}
+
+	writeJSON(w, http.StatusOK, txnResponse{Json: resp.GetJson(), TxnID: txnID})
+}
+
+// mutateRequest is the POST /v1/mutate body: a single mutation in either
+// JSON or RDF N-Quad form, optionally scoped to an existing transaction.
+type mutateRequest struct {
+	SetJSON    json.RawMessage `json:"set_json,omitempty"`
+	DeleteJSON json.RawMessage `json:"delete_json,omitempty"`
+	SetNquads  string          `json:"set_nquads,omitempty"`
+	DelNquads  string          `json:"del_nquads,omitempty"`
+	CommitNow  bool            `json:"commit_now,omitempty"`
+	TxnID      string          `json:"txn_id,omitempty"`
+}
+
+func (gw *gateway) handleMutate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req mutateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	mutation := &api.Mutation{
+		SetJson:    req.SetJSON,
+		DeleteJson: req.DeleteJSON,
+		SetNquads:  []byte(req.SetNquads),
+		DelNquads:  []byte(req.DelNquads),
+		CommitNow:  req.CommitNow,
+	}
+	apiReq := &api.Request{
+		Mutations: []*api.Mutation{mutation},
+		CommitNow: req.CommitNow,
+	}
+	if req.TxnID != "" {
+		txn, ok := gw.getTxn(req.TxnID)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown txn_id"})
+			return
+		}
+		apiReq.StartTs = txn.startTs
+	}
+
+	ctx := withAuthorization(r.Context(), r)
+	resp, err := gw.client.Query(ctx, apiReq)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	txnID := req.TxnID
+	switch {
+	case req.CommitNow:
+		if txnID != "" {
+			gw.dropTxn(txnID)
+		}
+		txnID = ""
+	case txnID == "" && resp.GetTxn() != nil:
+		txnID = gw.putTxn(resp.GetTxn())
+	case txnID != "":
+		gw.mergeTxn(txnID, resp.GetTxn())
+	}
+
+	writeJSON(w, http.StatusOK, txnResponse{Json: resp.GetJson(), TxnID: txnID})
+}
+
+// alterRequest is the POST /v1/alter body: a DQL schema change, or a
+// drop-all/drop-attr/drop-data operation.
+type alterRequest struct {
+	Schema   string `json:"schema,omitempty"`
+	DropAll  bool   `json:"drop_all,omitempty"`
+	DropAttr string `json:"drop_attr,omitempty"`
+	DropOp   string `json:"drop_op,omitempty"` // "", "DATA"
+}
+
+func (gw *gateway) handleAlter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req alterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	op := &api.Operation{
+		Schema:    req.Schema,
+		DropAll:   req.DropAll,
+		DropAttr:  req.DropAttr,
+		DropValue: "",
+	}
+	if strings.EqualFold(req.DropOp, "DATA") {
+		op.DropOp = api.Operation_DATA
+	}
+
+	ctx := withAuthorization(r.Context(), r)
+	if _, err := gw.client.Alter(ctx, op); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleTxn serves POST /v1/txn/{id}/commit and POST /v1/txn/{id}/abort.
+func (gw *gateway) handleTxn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/txn/")
+	id, action, ok := strings.Cut(path, "/")
+	if !ok || id == "" || (action != "commit" && action != "abort") {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "expected /v1/txn/{id}/commit or /v1/txn/{id}/abort"})
+		return
+	}
+
+	txn, ok := gw.getTxn(id)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown txn_id"})
+		return
+	}
+	defer gw.dropTxn(id)
+
+	txnCtx := txn.context()
+	txnCtx.Aborted = action == "abort"
+
+	ctx := withAuthorization(r.Context(), r)
+	if _, err := gw.client.CommitOrAbort(ctx, txnCtx); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": action + "ted"})
+}
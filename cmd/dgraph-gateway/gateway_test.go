@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"google.golang.org/grpc"
+)
+
+// fakeDgraphClient is a minimal api.DgraphClient stub for exercising the
+// gateway's HTTP handlers without a real Dgraph cluster.
+type fakeDgraphClient struct {
+	queryResp  *api.Response
+	queryResps []*api.Response // if set, consumed in order, one per Query call
+	queryErr   error
+	alterErr   error
+	commitResp *api.TxnContext
+	commitErr  error
+	lastReq    *api.Request
+	lastTxn    *api.TxnContext
+}
+
+func (f *fakeDgraphClient) Login(ctx context.Context, in *api.LoginRequest, opts ...grpc.CallOption) (*api.Response, error) {
+	return nil, nil
+}
+
+func (f *fakeDgraphClient) Query(ctx context.Context, in *api.Request, opts ...grpc.CallOption) (*api.Response, error) {
+	f.lastReq = in
+	if len(f.queryResps) > 0 {
+		resp := f.queryResps[0]
+		f.queryResps = f.queryResps[1:]
+		return resp, f.queryErr
+	}
+	return f.queryResp, f.queryErr
+}
+
+func (f *fakeDgraphClient) Alter(ctx context.Context, in *api.Operation, opts ...grpc.CallOption) (*api.Payload, error) {
+	return &api.Payload{}, f.alterErr
+}
+
+func (f *fakeDgraphClient) CommitOrAbort(ctx context.Context, in *api.TxnContext, opts ...grpc.CallOption) (*api.TxnContext, error) {
+	f.lastTxn = in
+	return f.commitResp, f.commitErr
+}
+
+func (f *fakeDgraphClient) CheckVersion(ctx context.Context, in *api.Check, opts ...grpc.CallOption) (*api.Version, error) {
+	return nil, nil
+}
+
+func TestHandleQueryStartsNewTxn(t *testing.T) {
+	client := &fakeDgraphClient{queryResp: &api.Response{
+		Json: []byte(`{"q":[]}`),
+		Txn:  &api.TxnContext{StartTs: 7},
+	}}
+	gw := newGateway(client)
+
+	body := strings.NewReader(`{"query":"schema {}"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/query", body)
+	rec := httptest.NewRecorder()
+
+	gw.handleQuery(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp txnResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.TxnID == "" {
+		t.Fatal("expected a non-empty txn_id for a new transaction")
+	}
+	if _, ok := gw.getTxn(resp.TxnID); !ok {
+		t.Fatalf("txn %q was not stored in the gateway", resp.TxnID)
+	}
+}
+
+func TestHandleQueryContinuesExistingTxn(t *testing.T) {
+	client := &fakeDgraphClient{queryResp: &api.Response{
+		Json: []byte(`{}`),
+		Txn:  &api.TxnContext{StartTs: 7},
+	}}
+	gw := newGateway(client)
+	txnID := gw.putTxn(&api.TxnContext{StartTs: 7})
+
+	body := strings.NewReader(`{"query":"schema {}","txn_id":"` + txnID + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/query", body)
+	rec := httptest.NewRecorder()
+
+	gw.handleQuery(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if client.lastReq.StartTs != 7 {
+		t.Errorf("outgoing request StartTs = %d, want 7 (carried over from txn_id)", client.lastReq.StartTs)
+	}
+
+	var resp txnResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.TxnID != txnID {
+		t.Errorf("response txn_id = %q, want %q", resp.TxnID, txnID)
+	}
+}
+
+func TestHandleQueryUnknownTxnID(t *testing.T) {
+	gw := newGateway(&fakeDgraphClient{})
+
+	body := strings.NewReader(`{"query":"schema {}","txn_id":"does-not-exist"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/query", body)
+	rec := httptest.NewRecorder()
+
+	gw.handleQuery(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleMutateCommitNowDropsTxn(t *testing.T) {
+	client := &fakeDgraphClient{queryResp: &api.Response{Json: []byte(`{}`)}}
+	gw := newGateway(client)
+	txnID := gw.putTxn(&api.TxnContext{StartTs: 3})
+
+	body := strings.NewReader(`{"set_nquads":"_:a <name> \"x\" .","commit_now":true,"txn_id":"` + txnID + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/mutate", body)
+	rec := httptest.NewRecorder()
+
+	gw.handleMutate(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if _, ok := gw.getTxn(txnID); ok {
+		t.Fatalf("txn %q should have been dropped after commit_now", txnID)
+	}
+
+	var resp txnResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.TxnID != "" {
+		t.Errorf("response txn_id = %q, want empty after commit_now", resp.TxnID)
+	}
+}
+
+func TestHandleTxnCommitAndAbort(t *testing.T) {
+	client := &fakeDgraphClient{commitResp: &api.TxnContext{}}
+	gw := newGateway(client)
+
+	commitID := gw.putTxn(&api.TxnContext{StartTs: 1})
+	req := httptest.NewRequest(http.MethodPost, "/v1/txn/"+commitID+"/commit", nil)
+	rec := httptest.NewRecorder()
+	gw.handleTxn(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("commit status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if client.lastTxn.Aborted {
+		t.Error("commit should send Aborted=false")
+	}
+	if _, ok := gw.getTxn(commitID); ok {
+		t.Error("txn should be dropped after commit")
+	}
+
+	abortID := gw.putTxn(&api.TxnContext{StartTs: 2})
+	req = httptest.NewRequest(http.MethodPost, "/v1/txn/"+abortID+"/abort", nil)
+	rec = httptest.NewRecorder()
+	gw.handleTxn(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("abort status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !client.lastTxn.Aborted {
+		t.Error("abort should send Aborted=true")
+	}
+}
+
+// TestHandleQueryAccumulatesKeysAcrossRequests is a regression test: the
+// gateway used to overwrite its stored TxnContext with each response
+// instead of merging Keys/Preds, so commit only ever saw the last
+// request's conflict-detection footprint.
+func TestHandleQueryAccumulatesKeysAcrossRequests(t *testing.T) {
+	client := &fakeDgraphClient{queryResps: []*api.Response{
+		{Json: []byte(`{}`), Txn: &api.TxnContext{StartTs: 5, Keys: []string{"k1"}, Preds: []string{"p1"}}},
+		{Json: []byte(`{}`), Txn: &api.TxnContext{StartTs: 5, Keys: []string{"k2"}, Preds: []string{"p2"}}},
+	}}
+	gw := newGateway(client)
+
+	firstBody := strings.NewReader(`{"query":"schema {}"}`)
+	rec := httptest.NewRecorder()
+	gw.handleQuery(rec, httptest.NewRequest(http.MethodPost, "/v1/query", firstBody))
+
+	var first txnResponse
+	if err := json.NewDecoder(rec.Body).Decode(&first); err != nil {
+		t.Fatalf("decoding first response: %v", err)
+	}
+
+	secondBody := strings.NewReader(`{"query":"schema {}","txn_id":"` + first.TxnID + `"}`)
+	rec = httptest.NewRecorder()
+	gw.handleQuery(rec, httptest.NewRequest(http.MethodPost, "/v1/query", secondBody))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("second query status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	txn, ok := gw.getTxn(first.TxnID)
+	if !ok {
+		t.Fatalf("txn %q missing after second request", first.TxnID)
+	}
+	for _, key := range []string{"k1", "k2"} {
+		if _, ok := txn.keys[key]; !ok {
+			t.Errorf("accumulated keys = %+v, missing %q", txn.keys, key)
+		}
+	}
+	for _, pred := range []string{"p1", "p2"} {
+		if _, ok := txn.preds[pred]; !ok {
+			t.Errorf("accumulated preds = %+v, missing %q", txn.preds, pred)
+		}
+	}
+
+	commitReq := httptest.NewRequest(http.MethodPost, "/v1/txn/"+first.TxnID+"/commit", nil)
+	rec = httptest.NewRecorder()
+	gw.handleTxn(rec, commitReq)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("commit status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if len(client.lastTxn.Keys) != 2 || len(client.lastTxn.Preds) != 2 {
+		t.Errorf("commit sent Keys=%v Preds=%v, want 2 of each (accumulated across both requests)", client.lastTxn.Keys, client.lastTxn.Preds)
+	}
+}
@@ -0,0 +1,110 @@
+// Command dgraph-gateway exposes a subset of Dgraph's DQL API (query,
+// mutate, alter, and multi-request transaction commit/abort) over
+// HTTP/JSON, for teams that can't or won't speak gRPC directly (browsers,
+// mobile, edge workers). It dials Dgraph through the same shared dialer
+// as the rest of this module, so TLS, auth, and endpoint parsing all
+// come for free; see pkg/dgraphcli and pkg/dgraphconn.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphcli"
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphconn"
+)
+
+func main() {
+	cf := dgraphcli.RegisterConnFlags(flag.CommandLine)
+	httpAddr := flag.String("http-addr", ":8180", "address the REST gateway listens on")
+	flag.Parse()
+
+	ctx := context.Background()
+	endpoint, tlsOpts, acl, auth, err := cf.Resolve(ctx)
+	if err != nil {
+		log.Fatalf("dgraph-gateway: %v", err)
+	}
+	if acl != nil {
+		defer acl.Close()
+	}
+
+	conn, err := dgraphconn.Dial(ctx, dgraphconn.Options{
+		Endpoint: endpoint,
+		Timeout:  5 * time.Second,
+		TLS:      tlsOpts,
+		Auth:     auth,
+	})
+	if err != nil {
+		log.Fatalf("dgraph-gateway: dialing %s: %v", endpoint, err)
+	}
+	defer conn.Close()
+
+	gw := newGateway(api.NewDgraphClient(conn))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/query", gw.handleQuery)
+	mux.HandleFunc("/v1/mutate", gw.handleMutate)
+	mux.HandleFunc("/v1/alter", gw.handleAlter)
+	mux.HandleFunc("/v1/txn/", gw.handleTxn)
+	mux.HandleFunc("/openapi.yaml", serveOpenAPISpec)
+
+	log.Printf("dgraph-gateway: listening on %s, proxying to %s", *httpAddr, endpoint)
+	if err := http.ListenAndServe(*httpAddr, mux); err != nil {
+		log.Fatalf("dgraph-gateway: %v", err)
+	}
+}
+
+// withAuthorization propagates the REST caller's Authorization header
+// (if any) into the outgoing gRPC metadata, so a multi-tenant gateway can
+// pass through per-request Dgraph ACL tokens rather than only ever using
+// the gateway's own configured auth.
+func withAuthorization(ctx context.Context, r *http.Request) context.Context {
+	if authz := r.Header.Get("Authorization"); authz != "" {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", authz)
+	}
+	return ctx
+}
+
+// writeError maps a Dgraph gRPC error to a JSON body and an appropriate
+// HTTP status, so REST clients get real status codes instead of a flat
+// 500 for everything.
+func writeError(w http.ResponseWriter, err error) {
+	httpStatus := http.StatusInternalServerError
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		httpStatus = http.StatusBadRequest
+	case codes.Unauthenticated:
+		httpStatus = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		httpStatus = http.StatusForbidden
+	case codes.NotFound:
+		httpStatus = http.StatusNotFound
+	case codes.Aborted, codes.AlreadyExists:
+		httpStatus = http.StatusConflict
+	case codes.Unavailable, codes.DeadlineExceeded:
+		httpStatus = http.StatusServiceUnavailable
+	}
+	writeJSON(w, httpStatus, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Println("dgraph-gateway: encoding response:", err)
+	}
+}
+
+func serveOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, "openapi.yaml")
+}
@@ -1,25 +1,37 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/dgraph-io/dgo/v210/protos/api"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphauth"
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphcli"
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphconn"
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphhealth"
 )
 
-// Test basic gRPC connectivity
-func testGRPCConnection() {
+// Test basic gRPC connectivity, including TLS/mTLS and per-RPC auth if
+// configured. When auth is set, also runs an end-to-end login -> schema
+// query -> logout probe so users can validate their ACL/API-key setup,
+// not just the TCP/TLS handshake.
+func testGRPCConnection(endpoint string, tlsOpts dgraphconn.TLSOptions, auth dgraphauth.Authenticator, acl *dgraphauth.ACLAuthenticator) {
 	fmt.Println("🔄 Testing gRPC connection to Dgraph...")
 
-	// Create connection to Dgraph gRPC server
-	conn, err := grpc.Dial(
-		"localhost:9080",
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-		grpc.WithTimeout(5*time.Second),
-	)
+	ctx := context.Background()
+	conn, err := dgraphconn.Dial(ctx, dgraphconn.Options{
+		Endpoint: endpoint,
+		Timeout:  5 * time.Second,
+		TLS:      tlsOpts,
+		Auth:     auth,
+	})
 	if err != nil {
 		log.Printf("❌ Failed to connect: %v", err)
 		return
@@ -29,25 +41,193 @@ func testGRPCConnection() {
 	fmt.Println("✅ gRPC connection established successfully")
 	fmt.Printf("📊 Connection state: %s\n", conn.GetState().String())
 
-	// Note: We can't import dgraph packages without proper setup,
-	// but the connection test above verifies gRPC is working
+	if tlsOpts.Enabled() {
+		parsed, err := dgraphconn.ParseEndpoint(endpoint)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return
+		}
+		peer, err := dgraphconn.InspectPeer(ctx, parsed.Target, tlsOpts)
+		if err != nil {
+			log.Printf("❌ TLS peer inspection failed: %v", err)
+			return
+		}
+		fmt.Printf("🔐 Cipher suite: %s\n", peer.CipherSuite)
+		fmt.Printf("🔐 Peer subject CN: %s\n", peer.SubjectCN)
+		fmt.Printf("🔐 Peer SANs: %v\n", peer.SANs)
+		fmt.Printf("🔐 Cert expiry: %s\n", peer.NotAfter.Format(time.RFC3339))
+	}
+
+	if auth != nil {
+		if err := probeAuth(ctx, conn, acl); err != nil {
+			log.Printf("❌ Auth probe failed: %v", err)
+			return
+		}
+	}
+
+	// Note: beyond the schema probe above (when auth is configured), we
+	// don't depend on the rest of the Dgraph client, but the connection
+	// test above verifies gRPC is working
 	fmt.Println("🎉 gRPC connectivity test: PASS")
 }
 
+// probeAuth runs the login -> schema query -> logout sequence a caller
+// needs to validate that its auth configuration actually works against
+// the cluster, not just that the transport dials.
+func probeAuth(ctx context.Context, conn *grpc.ClientConn, acl *dgraphauth.ACLAuthenticator) error {
+	client := api.NewDgraphClient(conn)
+
+	resp, err := client.Query(ctx, &api.Request{Query: "schema {}", ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("schema query: %w", err)
+	}
+	fmt.Printf("🔑 Auth probe: schema query succeeded (%d bytes)\n", len(resp.GetJson()))
+
+	if acl != nil {
+		acl.Logout()
+		fmt.Println("🔑 Auth probe: logged out (cached ACL tokens cleared)")
+	}
+	return nil
+}
+
+// runGenCert implements the "gencert" subcommand: a self-signed CA plus a
+// server/client certificate pair so users can exercise --tls-ca/--tls-cert
+// without hunting down openssl recipes.
+func runGenCert(args []string) {
+	fs := flag.NewFlagSet("gencert", flag.ExitOnError)
+	outDir := fs.String("out", "./certs", "directory to write the CA/server/client PEM bundle to")
+	serverNames := fs.String("server-names", "localhost,0.0.0.0", "comma-separated SANs for the server certificate")
+	fs.Parse(args)
+
+	var names []string
+	for _, n := range strings.Split(*serverNames, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+
+	bundle, err := dgraphconn.GenerateCertBundle(*outDir, names)
+	if err != nil {
+		log.Fatalf("❌ Failed to generate cert bundle: %v", err)
+	}
+
+	fmt.Println("✅ Generated self-signed CA + server + client bundle:")
+	fmt.Printf("   CA cert:      %s\n", bundle.CACert)
+	fmt.Printf("   Server cert:  %s\n", bundle.ServerCert)
+	fmt.Printf("   Server key:   %s\n", bundle.ServerKey)
+	fmt.Printf("   Client cert:  %s\n", bundle.ClientCert)
+	fmt.Printf("   Client key:   %s\n", bundle.ClientKey)
+	fmt.Println()
+	fmt.Println("💡 Point Dgraph Alpha at the server pair with --tls, and this")
+	fmt.Println("   tester at the client pair with --tls-cert/--tls-key.")
+}
+
+// runHealth implements the "health" subcommand: a one-shot or --watch
+// readiness probe built on pkg/dgraphhealth, dialed with retrying
+// interceptors so a momentary blip doesn't fail the probe outright.
+func runHealth(args []string) {
+	fs := flag.NewFlagSet("health", flag.ExitOnError)
+	cf := dgraphcli.RegisterConnFlags(fs)
+	watch := fs.Bool("watch", false, "keep probing on --watch-interval instead of exiting after one probe")
+	watchInterval := fs.Duration("watch-interval", 10*time.Second, "interval between probes in --watch mode")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	endpoint, tlsOpts, acl, auth, err := cf.Resolve(ctx)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if acl != nil {
+		defer acl.Close()
+	}
+
+	retry := dgraphhealth.DefaultRetryConfig
+	conn, err := dgraphconn.Dial(ctx, dgraphconn.Options{
+		Endpoint:      endpoint,
+		Timeout:       5 * time.Second,
+		TLS:           tlsOpts,
+		Auth:          auth,
+		ExtraDialOpts: retry.DialOptions(),
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	opts := dgraphhealth.Options{Conn: conn}
+
+	if !*watch {
+		report, err := dgraphhealth.Probe(ctx, opts)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		printHealthReport(report)
+		if !report.Healthy {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := dgraphhealth.Watch(ctx, opts, *watchInterval, printHealthReport); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+func printHealthReport(report *dgraphhealth.Report) {
+	status := "✅ healthy"
+	if !report.Healthy {
+		status = "❌ unhealthy"
+	}
+	fmt.Printf("[%s] %s\n", time.Now().Format(time.RFC3339), status)
+	for _, c := range report.Checks {
+		mark := "✅"
+		if !c.OK {
+			mark = "❌"
+		}
+		fmt.Printf("   %s %-16s %8s", mark, c.Name, c.Latency.Round(time.Millisecond))
+		if c.Err != nil {
+			fmt.Printf("  %v", c.Err)
+		}
+		fmt.Println()
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "gencert":
+			runGenCert(os.Args[2:])
+			return
+		case "health":
+			runHealth(os.Args[2:])
+			return
+		}
+	}
+
+	cf := dgraphcli.RegisterConnFlags(flag.CommandLine)
+	flag.Parse()
+
+	endpoint, tlsOpts, acl, auth, err := cf.Resolve(context.Background())
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if acl != nil {
+		defer acl.Close()
+	}
+
 	fmt.Println("🚀 Dgraph gRPC Connection Tester (Go)")
 	fmt.Println("======================================")
 
-	testGRPCConnection()
+	testGRPCConnection(endpoint, tlsOpts, auth, acl)
 
 	fmt.Println()
 	fmt.Println("📋 Test Summary:")
-	fmt.Println("   - gRPC Port: 9080")
+	fmt.Printf("   - Endpoint: %s\n", endpoint)
 	fmt.Println("   - Protocol: gRPC with Protocol Buffers")
 	fmt.Println("   - Connection: Established successfully")
 	fmt.Println()
 	fmt.Println("💡 For production use:")
-	fmt.Println("   - Use grpc.WithTransportCredentials() for TLS")
+	fmt.Println("   - Run `gencert` to produce a local CA + server + client bundle")
+	fmt.Println("   - Run `health [--watch]` for a readiness probe with retries")
 	fmt.Println("   - Implement connection pooling")
-	fmt.Println("   - Add proper error handling and retries")
-}
\ No newline at end of file
+}
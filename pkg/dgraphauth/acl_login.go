@@ -0,0 +1,78 @@
+package dgraphauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+)
+
+// loginResponse mirrors the JSON body of Dgraph's LoginResponse.Json,
+// which carries the two JWTs Dgraph.Login returns.
+type loginResponse struct {
+	AccessJwt  string `json:"accessJwt"`
+	RefreshJwt string `json:"refreshJwt"`
+}
+
+// jwtClaims is the handful of standard claims this package needs out of
+// the access JWT; Dgraph's access tokens are not re-verified here (the
+// channel is already authenticated via the gRPC connection), only
+// decoded to learn when to refresh.
+type jwtClaims struct {
+	Exp float64 `json:"exp"`
+}
+
+// NewDgraphACLLogin returns a LoginFunc that drives Dgraph's native ACL
+// login flow against client: a fresh Dgraph.Login with userID/password
+// when refreshJwt is empty, and a refresh-token login otherwise.
+func NewDgraphACLLogin(client api.DgraphClient, userID, password string) LoginFunc {
+	return func(ctx context.Context, refreshJwt string) (accessJwt, newRefreshJwt string, accessExpiry time.Time, err error) {
+		req := &api.LoginRequest{RefreshToken: refreshJwt}
+		if refreshJwt == "" {
+			req.Userid = userID
+			req.Password = password
+		}
+
+		resp, err := client.Login(ctx, req)
+		if err != nil {
+			return "", "", time.Time{}, fmt.Errorf("Dgraph.Login: %w", err)
+		}
+
+		var body loginResponse
+		if err := json.Unmarshal(resp.Json, &body); err != nil {
+			return "", "", time.Time{}, fmt.Errorf("parsing login response: %w", err)
+		}
+
+		expiry, err := jwtExpiry(body.AccessJwt)
+		if err != nil {
+			return "", "", time.Time{}, fmt.Errorf("parsing access JWT expiry: %w", err)
+		}
+
+		return body.AccessJwt, body.RefreshJwt, expiry, nil
+	}
+}
+
+// jwtExpiry decodes (without verifying) the "exp" claim of a JWT's
+// payload segment.
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("malformed JWT: expected 3 segments, got %d", len(parts))
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("decoding JWT payload: %w", err)
+	}
+
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("unmarshalling JWT claims: %w", err)
+	}
+
+	return time.Unix(int64(claims.Exp), 0), nil
+}
@@ -0,0 +1,51 @@
+package dgraphauth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func makeJWT(t *testing.T, exp float64) string {
+	t.Helper()
+	payload, err := json.Marshal(jwtClaims{Exp: exp})
+	if err != nil {
+		t.Fatalf("marshalling claims: %v", err)
+	}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return header + "." + body + ".unverified-signature"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	want := time.Unix(1700000000, 0)
+	token := makeJWT(t, float64(want.Unix()))
+
+	got, err := jwtExpiry(token)
+	if err != nil {
+		t.Fatalf("jwtExpiry: unexpected error: %v", err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("jwtExpiry = %v, want %v", got, want)
+	}
+}
+
+func TestJWTExpiryMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "too few segments", token: "onlyonesegment"},
+		{name: "bad base64 payload", token: "header.not-valid-base64!!.sig"},
+		{name: "payload not JSON", token: "header." + base64.RawURLEncoding.EncodeToString([]byte("not json")) + ".sig"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := jwtExpiry(tt.token); err == nil {
+				t.Errorf("jwtExpiry(%q): expected error, got none", tt.token)
+			}
+		})
+	}
+}
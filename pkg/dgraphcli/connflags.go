@@ -0,0 +1,152 @@
+// Package dgraphcli holds the command-line flag plumbing shared by every
+// binary in this module that dials a Dgraph Alpha: endpoint/TLS/auth
+// flags and the logic to turn them into a dgraphconn.Options plus an
+// optional dgraphauth.Authenticator.
+package dgraphcli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphauth"
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphconn"
+)
+
+// ConnFlags are the endpoint/TLS/auth flags shared by every subcommand
+// that needs to dial an Alpha.
+type ConnFlags struct {
+	endpoint        *string
+	tlsCA           *string
+	tlsCert         *string
+	tlsKey          *string
+	serverName      *string
+	aclUser         *string
+	aclPassword     *string
+	apiKey          *string
+	bearerTokenFile *string
+	basicUser       *string
+	basicPassword   *string
+}
+
+// RegisterConnFlags adds the shared connection flags to fs.
+func RegisterConnFlags(fs *flag.FlagSet) *ConnFlags {
+	defaultEndpoint := "localhost:9080"
+	if envEndpoint := os.Getenv(dgraphconn.EndpointEnvVar); envEndpoint != "" {
+		defaultEndpoint = envEndpoint
+	}
+
+	return &ConnFlags{
+		endpoint:        fs.String("endpoint", defaultEndpoint, "Dgraph Alpha gRPC endpoint: host:port, grpc://, grpcs://, dns:///, or unix:/// (env "+dgraphconn.EndpointEnvVar+")"),
+		tlsCA:           fs.String("tls-ca", "", "PEM file with the CA that signed the server certificate; enables TLS"),
+		tlsCert:         fs.String("tls-cert", "", "PEM file with a client certificate, for mutual TLS"),
+		tlsKey:          fs.String("tls-key", "", "PEM file with the client certificate's private key, for mutual TLS"),
+		serverName:      fs.String("server-name", "", "expected server name for SNI/SAN verification (defaults to the endpoint's host)"),
+		aclUser:         fs.String("acl-user", "", "Dgraph ACL username; enables ACL login auth"),
+		aclPassword:     fs.String("acl-password", "", "Dgraph ACL password"),
+		apiKey:          fs.String("api-key", "", "Dgraph Cloud/Slash API key; sent as a bearer token"),
+		bearerTokenFile: fs.String("bearer-token-file", "", "file containing a pre-minted bearer/JWT token"),
+		basicUser:       fs.String("basic-user", "", "username for HTTP Basic auth (reverse-proxied deployments)"),
+		basicPassword:   fs.String("basic-password", "", "password for HTTP Basic auth"),
+	}
+}
+
+// Resolve turns the parsed flags into a dial-ready endpoint, TLS config,
+// and authenticator (if any auth flags were set).
+func (f *ConnFlags) Resolve(ctx context.Context) (endpoint string, tlsOpts dgraphconn.TLSOptions, acl *dgraphauth.ACLAuthenticator, auth dgraphauth.Authenticator, err error) {
+	endpoint = *f.endpoint
+	serverName := *f.serverName
+	if serverName == "" {
+		parsed, err := dgraphconn.ParseEndpoint(endpoint)
+		if err != nil {
+			return "", dgraphconn.TLSOptions{}, nil, nil, err
+		}
+		target := parsed.Target
+		if u, err := url.Parse(target); err == nil && strings.Contains(target, "://") {
+			// dns:///host:port and xds:///host carry the host in the path.
+			target = strings.TrimPrefix(u.Path, "/")
+		}
+		if host, _, err := net.SplitHostPort(target); err == nil {
+			serverName = host
+		} else {
+			serverName = target
+		}
+	}
+
+	tlsOpts = dgraphconn.TLSOptions{
+		CAFile:     *f.tlsCA,
+		CertFile:   *f.tlsCert,
+		KeyFile:    *f.tlsKey,
+		ServerName: serverName,
+	}
+
+	acl, auth, err = BuildAuthenticator(ctx, endpoint, tlsOpts, AuthFlags{
+		ACLUser:         *f.aclUser,
+		ACLPassword:     *f.aclPassword,
+		APIKey:          *f.apiKey,
+		BearerTokenFile: *f.bearerTokenFile,
+		BasicUser:       *f.basicUser,
+		BasicPassword:   *f.basicPassword,
+	})
+	return endpoint, tlsOpts, acl, auth, err
+}
+
+// AuthFlags holds the raw flag values for every supported auth scheme;
+// at most one group may be populated.
+type AuthFlags struct {
+	ACLUser, ACLPassword string
+	APIKey               string
+	BearerTokenFile      string
+	BasicUser            string
+	BasicPassword        string
+}
+
+// BuildAuthenticator turns whichever auth flags were set into a
+// dgraphauth.Authenticator, dialing a bootstrap connection for ACL
+// (Dgraph.Login doesn't itself require auth). That bootstrap connection
+// stays open for the ACLAuthenticator's lifetime, since login is called
+// lazily and again on every refresh; callers must call the returned
+// *dgraphauth.ACLAuthenticator's Close method (alongside their main
+// connection's) once they're done with it. Returns (nil, nil, nil) if no
+// auth flags were given.
+func BuildAuthenticator(ctx context.Context, endpoint string, tlsOpts dgraphconn.TLSOptions, f AuthFlags) (*dgraphauth.ACLAuthenticator, dgraphauth.Authenticator, error) {
+	set := 0
+	for _, v := range []string{f.ACLUser, f.APIKey, f.BearerTokenFile, f.BasicUser} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, nil, fmt.Errorf("only one of --acl-user, --api-key, --bearer-token-file, --basic-user may be set")
+	}
+
+	switch {
+	case f.ACLUser != "":
+		bootstrap, err := dgraphconn.Dial(ctx, dgraphconn.Options{Endpoint: endpoint, Timeout: 5 * time.Second, TLS: tlsOpts})
+		if err != nil {
+			return nil, nil, fmt.Errorf("dialing bootstrap connection for ACL login: %w", err)
+		}
+		login := dgraphauth.NewDgraphACLLogin(api.NewDgraphClient(bootstrap), f.ACLUser, f.ACLPassword)
+		acl := dgraphauth.NewACLAuthenticator(login, bootstrap)
+		return acl, acl, nil
+	case f.APIKey != "":
+		return nil, dgraphauth.NewAPIKeyAuthenticator(f.APIKey), nil
+	case f.BearerTokenFile != "":
+		auth, err := dgraphauth.NewStaticTokenAuthenticator(f.BearerTokenFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, auth, nil
+	case f.BasicUser != "":
+		return nil, dgraphauth.NewBasicAuthenticator(f.BasicUser, f.BasicPassword), nil
+	default:
+		return nil, nil, nil
+	}
+}
@@ -0,0 +1,83 @@
+package dgraphcli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"google.golang.org/grpc"
+
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphconn"
+)
+
+// fakeACLServer implements just enough of api.DgraphServer to exercise
+// the ACL login path: Login returns a valid-shaped JWT pair.
+type fakeACLServer struct {
+	api.UnimplementedDgraphServer
+	logins int
+}
+
+func (s *fakeACLServer) Login(ctx context.Context, req *api.LoginRequest) (*api.Response, error) {
+	s.logins++
+	claims, _ := json.Marshal(struct {
+		Exp float64 `json:"exp"`
+	}{Exp: float64(time.Now().Add(time.Hour).Unix())})
+	jwt := "header." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+
+	body, _ := json.Marshal(struct {
+		AccessJwt  string `json:"accessJwt"`
+		RefreshJwt string `json:"refreshJwt"`
+	}{AccessJwt: jwt, RefreshJwt: "refresh-token"})
+	return &api.Response{Json: body}, nil
+}
+
+func startFakeACLServer(t *testing.T) (addr string, srv *fakeACLServer) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	srv = &fakeACLServer{}
+	s := grpc.NewServer()
+	api.RegisterDgraphServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	return lis.Addr().String(), srv
+}
+
+// TestBuildAuthenticatorACLLogin is a regression test for a bug where
+// BuildAuthenticator closed its ACL bootstrap connection before the
+// returned ACLAuthenticator ever used it, so the first (lazy) login
+// always failed with "the client connection is closing".
+func TestBuildAuthenticatorACLLogin(t *testing.T) {
+	addr, srv := startFakeACLServer(t)
+
+	acl, auth, err := BuildAuthenticator(context.Background(), addr, dgraphconn.TLSOptions{}, AuthFlags{
+		ACLUser:     "groot",
+		ACLPassword: "password",
+	})
+	if err != nil {
+		t.Fatalf("BuildAuthenticator: %v", err)
+	}
+	if acl == nil || auth == nil {
+		t.Fatal("BuildAuthenticator returned nil acl/auth for --acl-user")
+	}
+	defer acl.Close()
+
+	md, err := auth.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetRequestMetadata (lazy login): %v", err)
+	}
+	if md["accessJwt"] == "" {
+		t.Error("expected a non-empty accessJwt in the request metadata")
+	}
+	if srv.logins != 1 {
+		t.Errorf("server saw %d logins, want 1", srv.logins)
+	}
+}
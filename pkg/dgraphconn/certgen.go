@@ -0,0 +1,133 @@
+package dgraphconn
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CertBundle is the set of PEM files produced by GenerateCertBundle: a CA,
+// a server certificate signed by it (for the Dgraph Alpha's --tls flags),
+// and a client certificate for mutual TLS.
+type CertBundle struct {
+	CACert     string
+	ServerCert string
+	ServerKey  string
+	ClientCert string
+	ClientKey  string
+}
+
+// GenerateCertBundle writes a self-signed CA plus a server and client
+// certificate pair signed by it to dir, so a user can stand up a local
+// mTLS-enabled Dgraph Alpha (and this tester) without hand-rolling openssl
+// commands. serverNames is used as the server certificate's SANs, e.g.
+// []string{"localhost", "0.0.0.0"}.
+func GenerateCertBundle(dir string, serverNames []string) (*CertBundle, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+
+	caKey, caCert, caDER, err := generateCA()
+	if err != nil {
+		return nil, fmt.Errorf("generating CA: %w", err)
+	}
+	if err := writeCert(filepath.Join(dir, "ca.pem"), caDER); err != nil {
+		return nil, err
+	}
+
+	if err := generateSignedCert(dir, "server", caCert, caKey, serverNames, x509.ExtKeyUsageServerAuth); err != nil {
+		return nil, fmt.Errorf("generating server cert: %w", err)
+	}
+	if err := generateSignedCert(dir, "client", caCert, caKey, nil, x509.ExtKeyUsageClientAuth); err != nil {
+		return nil, fmt.Errorf("generating client cert: %w", err)
+	}
+
+	return &CertBundle{
+		CACert:     filepath.Join(dir, "ca.pem"),
+		ServerCert: filepath.Join(dir, "server.pem"),
+		ServerKey:  filepath.Join(dir, "server-key.pem"),
+		ClientCert: filepath.Join(dir, "client.pem"),
+		ClientKey:  filepath.Join(dir, "client-key.pem"),
+	}, nil
+}
+
+func generateCA() (*rsa.PrivateKey, *x509.Certificate, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dgraph-tester local CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(5, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return key, cert, der, nil
+}
+
+func generateSignedCert(dir, name string, caCert *x509.Certificate, caKey *rsa.PrivateKey, dnsNames []string, usage x509.ExtKeyUsage) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: fmt.Sprintf("dgraph-tester local %s", name)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     dnsNames,
+	}
+	for _, sn := range dnsNames {
+		if ip := net.ParseIP(sn); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return err
+	}
+
+	if err := writeCert(filepath.Join(dir, name+".pem"), der); err != nil {
+		return err
+	}
+	return writeKey(filepath.Join(dir, name+"-key.pem"), key)
+}
+
+func writeCert(path string, der []byte) error {
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+}
+
+func writeKey(path string, key *rsa.PrivateKey) error {
+	der := x509.MarshalPKCS1PrivateKey(key)
+	return os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der}), 0o600)
+}
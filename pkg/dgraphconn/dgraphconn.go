@@ -0,0 +1,211 @@
+// Package dgraphconn provides a reusable way to dial a Dgraph Alpha gRPC
+// endpoint, sharing TLS/mTLS setup across the connection tester and any
+// future tooling in this module.
+package dgraphconn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLSOptions configures the transport credentials used to reach a Dgraph
+// Alpha. The zero value means plaintext (insecure) gRPC.
+type TLSOptions struct {
+	// CAFile is a PEM file containing the CA (or chain) that signed the
+	// server certificate. Required to enable TLS.
+	CAFile string
+	// CertFile and KeyFile are an optional client certificate/key pair,
+	// presented for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name used for SNI and certificate
+	// verification. Defaults to the host portion of the dial target.
+	ServerName string
+}
+
+// Enabled reports whether TLS was requested.
+func (o TLSOptions) Enabled() bool {
+	return o.CAFile != ""
+}
+
+// Options bundles everything needed to dial a Dgraph Alpha.
+type Options struct {
+	// Endpoint is a host:port, or a scheme-qualified target understood
+	// by ParseEndpoint (grpc://, grpcs://, dns:///, unix:///).
+	Endpoint string
+	// DialTarget, if set, is passed to grpc.DialContext verbatim instead
+	// of Endpoint, bypassing ParseEndpoint's scheme allowlist entirely.
+	// It's for callers that register their own gRPC resolver.Builder
+	// under a scheme ParseEndpoint can't know about (see pkg/dgraphpool).
+	// Security is treated as SecurityUnset, same as a dns:/// or
+	// unix:/// target. Mutually exclusive with Endpoint.
+	DialTarget string
+	// Timeout bounds the blocking dial below.
+	Timeout time.Duration
+	TLS     TLSOptions
+	// Auth, if set, is sent as per-RPC credentials on every call (ACL
+	// token, API key, static bearer, or Basic). See pkg/dgraphauth.
+	Auth credentials.PerRPCCredentials
+	// ExtraDialOpts are appended after the options this package builds
+	// (transport credentials, per-RPC creds), e.g. retry interceptors
+	// from pkg/dgraphhealth.
+	ExtraDialOpts []grpc.DialOption
+}
+
+// Dial opens a blocking connection to a Dgraph Alpha using the given
+// options, returning the same diagnostics a caller needs to print before
+// handing the connection off to an api.Dgraph client.
+func Dial(ctx context.Context, opts Options) (*grpc.ClientConn, error) {
+	endpoint := Endpoint{Target: opts.DialTarget, Security: SecurityUnset}
+	if opts.DialTarget == "" {
+		var err error
+		endpoint, err = ParseEndpoint(opts.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	creds, err := transportCredentials(opts.TLS, endpoint.Security)
+	if err != nil {
+		return nil, fmt.Errorf("building transport credentials: %w", err)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithBlock(),
+	}
+	if opts.Auth != nil {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(opts.Auth))
+	}
+	dialOpts = append(dialOpts, opts.ExtraDialOpts...)
+
+	conn, err := grpc.DialContext(dialCtx, endpoint.Target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("dialing %s: %w", endpoint.Target, err)
+	}
+	return conn, nil
+}
+
+// transportCredentials builds plaintext or TLS credentials. An explicit
+// grpc:// or grpcs:// scheme in the endpoint overrides TLSOptions;
+// otherwise TLSOptions.Enabled() decides, as for a bare host:port or a
+// dns:///unix:// target.
+func transportCredentials(opts TLSOptions, security EndpointSecurity) (credentials.TransportCredentials, error) {
+	useTLS := opts.Enabled()
+	switch security {
+	case SecurityPlaintext:
+		useTLS = false
+	case SecurityTLS:
+		useTLS = true
+	}
+
+	if !useTLS {
+		return insecure.NewCredentials(), nil
+	}
+	if !opts.Enabled() {
+		return nil, fmt.Errorf("grpcs:// endpoint requires --tls-ca to build the TLS config")
+	}
+
+	tlsConfig, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// PeerInfo summarizes the TLS state of a handshake against a Dgraph
+// Alpha, for diagnostic printing by the tester.
+type PeerInfo struct {
+	CipherSuite string
+	SubjectCN   string
+	SANs        []string
+	NotAfter    time.Time
+}
+
+// InspectPeer performs a standalone TLS handshake against endpoint using
+// opts and returns the negotiated cipher suite plus the leaf
+// certificate's subject CN, SANs, and expiry. Standard certificate
+// verification (including ServerName/SAN matching) runs as part of the
+// handshake, so a mismatched --server-name surfaces here as a clear
+// error rather than a silent fallback.
+func InspectPeer(ctx context.Context, endpoint string, opts TLSOptions) (*PeerInfo, error) {
+	if !opts.Enabled() {
+		return nil, fmt.Errorf("TLS is not enabled (no --tls-ca given), nothing to inspect")
+	}
+
+	tlsConfig, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	rawConn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("dialing %s for TLS inspection: %w", endpoint, err)
+	}
+	defer rawConn.Close()
+
+	tlsConn := tls.Client(rawConn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("TLS handshake with %s failed (check --server-name against the cert's SANs): %w", endpoint, err)
+	}
+	defer tlsConn.Close()
+
+	state := tlsConn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("server presented no certificates")
+	}
+	leaf := state.PeerCertificates[0]
+
+	return &PeerInfo{
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		SubjectCN:   leaf.Subject.CommonName,
+		SANs:        leaf.DNSNames,
+		NotAfter:    leaf.NotAfter,
+	}, nil
+}
+
+// buildTLSConfig is the *tls.Config equivalent of transportCredentials,
+// used when a caller needs the raw config (e.g. for a standalone
+// handshake) rather than gRPC TransportCredentials.
+func buildTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	caPEM, err := os.ReadFile(opts.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading tls-ca %s: %w", opts.CAFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in tls-ca %s", opts.CAFile)
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:    pool,
+		ServerName: opts.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, fmt.Errorf("tls-cert and tls-key must be set together for mutual TLS")
+		}
+		clientCert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client cert/key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
+}
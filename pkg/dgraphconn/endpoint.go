@@ -0,0 +1,69 @@
+package dgraphconn
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// EndpointEnvVar is the environment variable consulted when no --endpoint
+// flag is given, so deployments can configure the tester without a CLI
+// flag (e.g. from a Kubernetes Secret/ConfigMap).
+const EndpointEnvVar = "DGRAPH_GRPC_ENDPOINT"
+
+// Endpoint is a parsed Dgraph Alpha gRPC target. Target is what gets
+// handed to grpc.DialContext verbatim; Security records whether the
+// scheme itself picked a transport (grpc/grpcs), leaving "unset" for
+// schemes like dns/unix where the caller's TLSOptions decide.
+type Endpoint struct {
+	Target   string
+	Security EndpointSecurity
+}
+
+// EndpointSecurity describes what, if anything, an endpoint's scheme says
+// about transport security.
+type EndpointSecurity int
+
+const (
+	// SecurityUnset means the scheme doesn't dictate a transport; fall
+	// back to whatever TLSOptions.Enabled() says.
+	SecurityUnset EndpointSecurity = iota
+	SecurityPlaintext
+	SecurityTLS
+)
+
+// ParseEndpoint parses a Dgraph Alpha endpoint in one of:
+//
+//	host:port              (bare, legacy form; plaintext or TLS per opts)
+//	grpc://host:port        (explicit plaintext)
+//	grpcs://host:port       (explicit TLS)
+//	dns:///cluster:port     (gRPC's built-in DNS resolver)
+//	unix:///var/run/d.sock  (gRPC's built-in Unix socket resolver)
+//
+// For dns/unix/xds the scheme is gRPC's own and is passed through
+// untouched so grpc.DialContext's resolver registry handles it.
+func ParseEndpoint(raw string) (Endpoint, error) {
+	// url.Parse treats anything before a bare ":" as a scheme (e.g.
+	// "localhost:9080" parses as Scheme="localhost", Opaque="9080"), so a
+	// missing "://" is the only reliable signal that raw is a bare
+	// host:port rather than a scheme-qualified target.
+	if !strings.Contains(raw, "://") {
+		return Endpoint{Target: raw, Security: SecurityUnset}, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return Endpoint{Target: raw, Security: SecurityUnset}, nil
+	}
+
+	switch u.Scheme {
+	case "grpc":
+		return Endpoint{Target: u.Host, Security: SecurityPlaintext}, nil
+	case "grpcs":
+		return Endpoint{Target: u.Host, Security: SecurityTLS}, nil
+	case "dns", "unix", "xds":
+		return Endpoint{Target: raw, Security: SecurityUnset}, nil
+	default:
+		return Endpoint{}, fmt.Errorf("unsupported endpoint scheme %q (want grpc, grpcs, dns, unix, or xds)", u.Scheme)
+	}
+}
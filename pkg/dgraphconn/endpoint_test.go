@@ -0,0 +1,43 @@
+package dgraphconn
+
+import "testing"
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantTarget string
+		wantSec    EndpointSecurity
+		wantErr    bool
+	}{
+		{name: "bare host:port", raw: "localhost:9080", wantTarget: "localhost:9080", wantSec: SecurityUnset},
+		{name: "bare host:port default-looking", raw: "127.0.0.1:9080", wantTarget: "127.0.0.1:9080", wantSec: SecurityUnset},
+		{name: "grpc scheme", raw: "grpc://localhost:9080", wantTarget: "localhost:9080", wantSec: SecurityPlaintext},
+		{name: "grpcs scheme", raw: "grpcs://localhost:9080", wantTarget: "localhost:9080", wantSec: SecurityTLS},
+		{name: "dns scheme passthrough", raw: "dns:///cluster:9080", wantTarget: "dns:///cluster:9080", wantSec: SecurityUnset},
+		{name: "unix scheme passthrough", raw: "unix:///var/run/dgraph.sock", wantTarget: "unix:///var/run/dgraph.sock", wantSec: SecurityUnset},
+		{name: "xds scheme passthrough", raw: "xds:///cluster", wantTarget: "xds:///cluster", wantSec: SecurityUnset},
+		{name: "unsupported scheme", raw: "ftp://localhost:9080", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep, err := ParseEndpoint(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseEndpoint(%q): expected error, got none", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEndpoint(%q): unexpected error: %v", tt.raw, err)
+			}
+			if ep.Target != tt.wantTarget {
+				t.Errorf("ParseEndpoint(%q).Target = %q, want %q", tt.raw, ep.Target, tt.wantTarget)
+			}
+			if ep.Security != tt.wantSec {
+				t.Errorf("ParseEndpoint(%q).Security = %v, want %v", tt.raw, ep.Security, tt.wantSec)
+			}
+		})
+	}
+}
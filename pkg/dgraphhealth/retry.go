@@ -0,0 +1,107 @@
+package dgraphhealth
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryConfig tunes the exponential backoff the unary/stream interceptors
+// below apply to retryable RPCs (UNAVAILABLE, DEADLINE_EXCEEDED).
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryConfig is a sane default for probing an Alpha that may be
+// mid-restart or behind a still-converging load balancer.
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts: 5,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns BaseDelay * 2^attempt, capped at MaxDelay, with up to
+// 20% jitter so a thundering herd of probes doesn't retry in lockstep.
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	delay := float64(c.BaseDelay) * math.Pow(2, float64(attempt))
+	if delay > float64(c.MaxDelay) {
+		delay = float64(c.MaxDelay)
+	}
+	jitter := delay * 0.2 * rand.Float64()
+	return time.Duration(delay + jitter)
+}
+
+// DialOptions returns unary and stream interceptors that retry
+// UNAVAILABLE/DEADLINE_EXCEEDED RPCs with exponential backoff and jitter,
+// for use with dgraphconn.Options.ExtraDialOpts.
+func (c RetryConfig) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(c.unaryRetryInterceptor()),
+		grpc.WithStreamInterceptor(c.streamRetryInterceptor()),
+	}
+}
+
+func (c RetryConfig) unaryRetryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+		for attempt := 0; attempt < c.MaxAttempts; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !isRetryable(err) {
+				return err
+			}
+			if attempt == c.MaxAttempts-1 {
+				break
+			}
+			select {
+			case <-time.After(c.backoff(attempt)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return err
+	}
+}
+
+// streamRetryInterceptor only retries the initial stream setup: once a
+// stream has started delivering messages, retrying would silently drop
+// state the caller already consumed, so mid-stream errors are returned
+// as-is.
+func (c RetryConfig) streamRetryInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var (
+			stream grpc.ClientStream
+			err    error
+		)
+		for attempt := 0; attempt < c.MaxAttempts; attempt++ {
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			if err == nil || !isRetryable(err) {
+				return stream, err
+			}
+			if attempt == c.MaxAttempts-1 {
+				break
+			}
+			select {
+			case <-time.After(c.backoff(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return stream, err
+	}
+}
@@ -0,0 +1,47 @@
+package dgraphhealth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "down"), want: true},
+		{name: "deadline exceeded", err: status.Error(codes.DeadlineExceeded, "timeout"), want: true},
+		{name: "invalid argument", err: status.Error(codes.InvalidArgument, "bad"), want: false},
+		{name: "non-status error", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	c := RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: 1 * time.Second}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		d := c.backoff(attempt)
+		if d < c.BaseDelay {
+			t.Errorf("backoff(%d) = %v, want >= BaseDelay %v", attempt, d, c.BaseDelay)
+		}
+		// Capped delay plus up to 20% jitter.
+		if max := c.MaxDelay + c.MaxDelay/5; d > max {
+			t.Errorf("backoff(%d) = %v, want <= %v (MaxDelay + 20%% jitter)", attempt, d, max)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package dgraphpool provides a load-balanced connection pool over one
+// or more Dgraph Alphas: a single *grpc.ClientConn configured with
+// gRPC's round_robin balancer and a resolver that periodically
+// re-resolves the Alpha list (static, or fetched from a cluster's
+// membership endpoint), plus a semaphore-bounded Acquire/release so
+// callers can observe and cap in-flight usage.
+package dgraphpool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/ayushmaanbhav/product-farm/pkg/dgraphconn"
+)
+
+// roundRobinServiceConfig selects grpc's built-in round_robin LB policy,
+// which opens one subconnection per address our resolver reports and
+// spreads RPCs across them.
+const roundRobinServiceConfig = `{"loadBalancingConfig": [{"round_robin": {}}]}`
+
+// Config configures a Pool.
+type Config struct {
+	// Endpoints is a static list of Alpha host:port addresses. Mutually
+	// exclusive with Fetcher.
+	Endpoints []string
+	// Fetcher, if set, is called on ReResolveInterval to refresh the
+	// Alpha address list from the cluster's own membership info (e.g.
+	// Dgraph Zero's /state endpoint). Mutually exclusive with Endpoints.
+	Fetcher AlphaListFetcher
+	// ReResolveInterval is how often Fetcher is polled. Defaults to 30s.
+	ReResolveInterval time.Duration
+
+	// Conn carries the shared TLS/auth dial options (see dgraphconn);
+	// its DialTarget field is overwritten (so its Endpoint is ignored)
+	// since the pool dials its own resolver-driven target.
+	Conn dgraphconn.Options
+
+	// MaxConcurrent bounds how many Acquire callers may hold a
+	// connection at once; additional callers block until one is
+	// released. Zero means unbounded.
+	MaxConcurrent int
+	// EvictAfter is how long the pool's connection may sit in
+	// TransientFailure before the pool forces a reconnect attempt.
+	// Defaults to 30s.
+	EvictAfter time.Duration
+
+	// Registerer is where pool metrics are registered. Defaults to
+	// prometheus.DefaultRegisterer.
+	Registerer prometheus.Registerer
+}
+
+// PooledConn is what Acquire hands back: the shared, load-balanced
+// connection. Acquire returns the release func the caller must call when
+// done alongside it.
+type PooledConn struct {
+	Conn *grpc.ClientConn
+}
+
+// Pool is a load-balanced, metrics-instrumented handle onto one or more
+// Dgraph Alphas.
+type Pool struct {
+	conn   *grpc.ClientConn
+	sem    chan struct{}
+	cancel context.CancelFunc
+
+	inFlight    prometheus.Gauge
+	acquireWait prometheus.Histogram
+	reconnects  prometheus.Counter
+}
+
+// New dials the pool's shared connection and starts its background
+// health watcher.
+func New(ctx context.Context, cfg Config) (*Pool, error) {
+	if (len(cfg.Endpoints) == 0) == (cfg.Fetcher == nil) {
+		return nil, fmt.Errorf("dgraphpool: exactly one of Config.Endpoints or Config.Fetcher must be set")
+	}
+	if cfg.ReResolveInterval == 0 {
+		cfg.ReResolveInterval = 30 * time.Second
+	}
+	if cfg.EvictAfter == 0 {
+		cfg.EvictAfter = 30 * time.Second
+	}
+	if cfg.Conn.Timeout == 0 {
+		cfg.Conn.Timeout = 10 * time.Second
+	}
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	fetch := cfg.Fetcher
+	if fetch == nil {
+		fetch = staticAlphaList(cfg.Endpoints)
+	}
+
+	builder := &stateResolverBuilder{scheme: nextScheme(), fetch: fetch, interval: cfg.ReResolveInterval}
+	resolver.Register(builder)
+
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
+	}, cfg.Conn.ExtraDialOpts...)
+	cfg.Conn.ExtraDialOpts = dialOpts
+	cfg.Conn.DialTarget = builder.scheme + ":///cluster"
+
+	conn, err := dgraphconn.Dial(ctx, cfg.Conn)
+	if err != nil {
+		return nil, fmt.Errorf("dgraphpool: dialing: %w", err)
+	}
+
+	var sem chan struct{}
+	if cfg.MaxConcurrent > 0 {
+		sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		conn:   conn,
+		sem:    sem,
+		cancel: cancel,
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "dgraphpool_in_flight", Help: "Connections currently held via Acquire.",
+		}),
+		acquireWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "dgraphpool_acquire_wait_seconds", Help: "Time Acquire spent waiting for a free slot.",
+		}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dgraphpool_reconnects_total", Help: "Forced reconnects after prolonged TransientFailure.",
+		}),
+	}
+	registerer.MustRegister(p.inFlight, p.acquireWait, p.reconnects)
+
+	go p.watch(watchCtx, cfg.EvictAfter)
+
+	return p, nil
+}
+
+// Acquire returns the pool's shared, load-balanced connection, blocking
+// if Config.MaxConcurrent callers already hold one. The caller must call
+// the returned release func exactly once.
+func (p *Pool) Acquire(ctx context.Context) (*PooledConn, func(), error) {
+	start := time.Now()
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	p.acquireWait.Observe(time.Since(start).Seconds())
+	p.inFlight.Inc()
+
+	release := func() {
+		p.inFlight.Dec()
+		if p.sem != nil {
+			<-p.sem
+		}
+	}
+	return &PooledConn{Conn: p.conn}, release, nil
+}
+
+// Close stops the background watcher and closes the shared connection.
+func (p *Pool) Close() error {
+	p.cancel()
+	return p.conn.Close()
+}
+
+// watch forces a reconnect attempt when the pool's connection has sat in
+// TransientFailure for longer than evictAfter, since a round_robin
+// subconnection that's wedged otherwise only retries on its own backoff
+// schedule.
+func (p *Pool) watch(ctx context.Context, evictAfter time.Duration) {
+	const pollInterval = 2 * time.Second
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var failingSince time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if p.conn.GetState() != connectivity.TransientFailure {
+			failingSince = time.Time{}
+			continue
+		}
+		if failingSince.IsZero() {
+			failingSince = time.Now()
+			continue
+		}
+		if time.Since(failingSince) >= evictAfter {
+			p.conn.ResetConnectBackoff()
+			p.reconnects.Inc()
+			failingSince = time.Now()
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package dgraphpool
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// AlphaListFetcher retrieves the current set of Alpha gRPC addresses for
+// a cluster, e.g. by parsing Dgraph Zero's /state HTTP endpoint. It's a
+// function rather than a concrete client so this package doesn't need a
+// hard dependency on however a deployment exposes that endpoint.
+type AlphaListFetcher func(ctx context.Context) ([]string, error)
+
+// schemeCounter lets each Pool register its own resolver.Builder under a
+// unique scheme; grpc-go's resolver registry is a single global map
+// keyed by scheme, so two pools can't share one.
+var schemeCounter uint64
+
+func nextScheme() string {
+	return fmt.Sprintf("dgraphpool%d", atomic.AddUint64(&schemeCounter, 1))
+}
+
+// stateResolverBuilder re-resolves the Alpha address list on a timer by
+// calling fetch, and pushes updates to grpc's round_robin balancer via
+// resolver.ClientConn.UpdateState.
+type stateResolverBuilder struct {
+	scheme   string
+	fetch    AlphaListFetcher
+	interval time.Duration
+}
+
+func (b *stateResolverBuilder) Scheme() string { return b.scheme }
+
+func (b *stateResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	r := &stateResolver{fetch: b.fetch, interval: b.interval, cc: cc, done: make(chan struct{})}
+	r.resolveOnce(context.Background())
+	go r.run()
+	return r, nil
+}
+
+// stateResolver is the resolver.Resolver returned by stateResolverBuilder.Build.
+type stateResolver struct {
+	fetch    AlphaListFetcher
+	interval time.Duration
+	cc       resolver.ClientConn
+	done     chan struct{}
+}
+
+func (r *stateResolver) run() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.resolveOnce(context.Background())
+		}
+	}
+}
+
+func (r *stateResolver) resolveOnce(ctx context.Context) {
+	addrs, err := r.fetch(ctx)
+	if err != nil {
+		r.cc.ReportError(err)
+		return
+	}
+
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(addrs))}
+	for _, a := range addrs {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: a})
+	}
+	_ = r.cc.UpdateState(state)
+}
+
+// ResolveNow is called by grpc when it wants a fresher address list
+// immediately, e.g. after a connection failure.
+func (r *stateResolver) ResolveNow(resolver.ResolveNowOptions) {
+	go r.resolveOnce(context.Background())
+}
+
+func (r *stateResolver) Close() {
+	close(r.done)
+}
+
+// staticAlphaList is an AlphaListFetcher for a fixed, known-upfront list
+// of Alpha endpoints, used when a caller passes Config.Endpoints instead
+// of Config.Fetcher.
+func staticAlphaList(endpoints []string) AlphaListFetcher {
+	return func(ctx context.Context) ([]string, error) {
+		return endpoints, nil
+	}
+}
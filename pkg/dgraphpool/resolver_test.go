@@ -0,0 +1,80 @@
+package dgraphpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// fakeClientConn is a minimal resolver.ClientConn stub recording the
+// updates a stateResolver sends it.
+type fakeClientConn struct {
+	resolver.ClientConn
+
+	mu     sync.Mutex
+	states []resolver.State
+	errs   []error
+}
+
+func (f *fakeClientConn) UpdateState(s resolver.State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states = append(f.states, s)
+	return nil
+}
+
+func (f *fakeClientConn) ReportError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs = append(f.errs, err)
+}
+
+func TestNextSchemeIsUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		s := nextScheme()
+		if seen[s] {
+			t.Fatalf("nextScheme returned %q twice", s)
+		}
+		seen[s] = true
+	}
+}
+
+func TestStateResolverResolveOnceUpdatesState(t *testing.T) {
+	cc := &fakeClientConn{}
+	r := &stateResolver{
+		fetch: staticAlphaList([]string{"alpha-1:9080", "alpha-2:9080"}),
+		cc:    cc,
+	}
+
+	r.resolveOnce(context.Background())
+
+	if len(cc.states) != 1 {
+		t.Fatalf("got %d UpdateState calls, want 1", len(cc.states))
+	}
+	got := cc.states[0].Addresses
+	if len(got) != 2 || got[0].Addr != "alpha-1:9080" || got[1].Addr != "alpha-2:9080" {
+		t.Errorf("UpdateState addresses = %+v, want [alpha-1:9080 alpha-2:9080]", got)
+	}
+}
+
+func TestStateResolverResolveOnceReportsFetchError(t *testing.T) {
+	cc := &fakeClientConn{}
+	wantErr := errors.New("zero unreachable")
+	r := &stateResolver{
+		fetch: func(ctx context.Context) ([]string, error) { return nil, wantErr },
+		cc:    cc,
+	}
+
+	r.resolveOnce(context.Background())
+
+	if len(cc.states) != 0 {
+		t.Errorf("got %d UpdateState calls, want 0 on fetch error", len(cc.states))
+	}
+	if len(cc.errs) != 1 || cc.errs[0] != wantErr {
+		t.Errorf("ReportError calls = %+v, want [%v]", cc.errs, wantErr)
+	}
+}